@@ -0,0 +1,154 @@
+package etcdbackup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ActionHandler exposes save/list/delete/prune as HTTP actions on the Cluster resource, backed
+// by the same EtcdBackup objects and BackendProvider/BackupStore machinery the reconciler
+// uses, so users don't have to hand-author an EtcdBackup CR for a one-off snapshot. The
+// cluster schema's action wiring (pkg/api/customization/cluster) registers "backupSave",
+// "backupList", "backupDelete" and "backupPrune" against the cluster resource and calls
+// ServeAction to handle them; NewActionHandler gives that wiring the handler instance backed
+// by this controller's clients.
+type ActionHandler struct {
+	Controller *Controller
+}
+
+// NewActionHandler builds the action handler for a running Controller, so the cluster schema
+// action wiring can reuse the same backup/restore clients and BackupStore dispatch as the
+// reconciler instead of constructing its own.
+func NewActionHandler(c *Controller) *ActionHandler {
+	return &ActionHandler{Controller: c}
+}
+
+// snapshotListEntry is one row in the `list` action's response: either a known EtcdBackup, or
+// an orphan object discovered in the bucket that has no matching CR.
+type snapshotListEntry struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size,omitempty"`
+	Orphan    bool   `json:"orphan"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+// ServeAction dispatches save/list/delete/prune. The caller (the cluster resource's action
+// wiring) is expected to have already authorized the request against the cluster.
+func (h *ActionHandler) ServeAction(action string, cluster *v3.Cluster, w http.ResponseWriter, r *http.Request) error {
+	switch action {
+	case "backupSave":
+		return h.save(cluster, w)
+	case "backupList":
+		return h.list(cluster, w)
+	case "backupDelete":
+		return h.delete(cluster, r, w)
+	case "backupPrune":
+		return h.prune(cluster, w)
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+}
+
+func (h *ActionHandler) save(cluster *v3.Cluster, w http.ResponseWriter) error {
+	if !isBackupSet(cluster.Spec.RancherKubernetesEngineConfig) {
+		return fmt.Errorf("[etcd-backup] cluster doesn't have a backup config")
+	}
+	newBackup := NewBackupObject(cluster, true)
+	v3.BackupConditionCreated.CreateUnknownIfNotExists(newBackup)
+	created, err := h.Controller.backupClient.Create(newBackup)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(created)
+}
+
+// list returns both the cluster's known EtcdBackup objects and any orphan objects found by
+// listing the configured off-cluster store, so drift between the bucket and the CR store is
+// visible to the caller.
+func (h *ActionHandler) list(cluster *v3.Cluster, w http.ResponseWriter) error {
+	backups, err := h.Controller.backupLister.List(cluster.Name, labels.NewSelector())
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	entries := make([]snapshotListEntry, 0, len(backups))
+	for _, b := range backups {
+		known[backupObjectName(b)] = true
+		entries = append(entries, snapshotListEntry{
+			Name:      b.Name,
+			Size:      b.Status.Size,
+			CreatedAt: b.CreationTimestamp.Format(time.RFC3339),
+		})
+	}
+
+	backupConfig := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig
+	if store, err := h.Controller.getBackupStore(backupConfig); err == nil {
+		objs, err := store.List("")
+		if err != nil {
+			logrus.Warnf("[etcd-backup] failed to list off-cluster snapshots for cluster [%s]: %v", cluster.Name, err)
+		} else {
+			for _, obj := range objs {
+				if known[obj.Name] {
+					continue
+				}
+				entries = append(entries, snapshotListEntry{Name: obj.Name, Size: obj.Size, Orphan: true})
+			}
+		}
+	}
+
+	return json.NewEncoder(w).Encode(entries)
+}
+
+func (h *ActionHandler) delete(cluster *v3.Cluster, r *http.Request, w http.ResponseWriter) error {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return err
+	}
+	if body.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	backup, err := h.Controller.backupLister.Get(cluster.Name, body.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if backup != nil {
+		if err := h.Controller.backupClient.DeleteNamespaced(cluster.Name, backup.Name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(map[string]string{"name": body.Name})
+	}
+
+	// no matching CR - this may be an orphan object, try deleting it straight from the store
+	backupConfig := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig
+	store, err := h.Controller.getBackupStore(backupConfig)
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(body.Name); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(map[string]string{"name": body.Name})
+}
+
+func (h *ActionHandler) prune(cluster *v3.Cluster, w http.ResponseWriter) error {
+	backups, err := h.Controller.getRecuringBackupsList(cluster)
+	if err != nil {
+		return err
+	}
+	if err := h.Controller.rotateExpiredBackups(cluster, backups); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(map[string]string{"status": "pruned"})
+}
@@ -5,8 +5,10 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -16,8 +18,10 @@ import (
 	"github.com/rancher/kontainer-engine/service"
 	"github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
 	"github.com/rancher/rancher/pkg/rkedialerfactory"
+	v1 "github.com/rancher/types/apis/core/v1"
 	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
 	"github.com/rancher/types/config"
+	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,18 +45,37 @@ type Controller struct {
 	backupLister          v3.EtcdBackupLister
 	backupDriver          *service.EngineService
 	KontainerDriverLister v3.KontainerDriverLister
+	secretLister          v1.SecretLister
+	// namespace scopes the controller to a single cluster namespace. Empty means
+	// cluster-wide, watching EtcdBackups across all namespaces.
+	namespace string
+	// ActionHandler serves the on-demand save/list/delete/prune HTTP actions mounted on the
+	// Cluster resource; exported so the cluster API's action wiring can reach it.
+	ActionHandler *ActionHandler
 }
 
+// Register starts the controller in cluster-wide mode, watching EtcdBackup objects across
+// every cluster namespace. This is the mode the management server runs in.
 func Register(ctx context.Context, management *config.ManagementContext) {
+	RegisterScoped(ctx, management, "")
+}
+
+// RegisterScoped starts the controller scoped to a single cluster namespace, matching the
+// `--cluster-wide=false` mode of standalone backup-operator deployments where one controller
+// instance is responsible for exactly one downstream cluster.
+func RegisterScoped(ctx context.Context, management *config.ManagementContext, namespace string) {
 	c := &Controller{
 		ctx:                   ctx,
 		clusterClient:         management.Management.Clusters(""),
 		clusterLister:         management.Management.Clusters("").Controller().Lister(),
-		backupClient:          management.Management.EtcdBackups(""),
-		backupLister:          management.Management.EtcdBackups("").Controller().Lister(),
+		backupClient:          management.Management.EtcdBackups(namespace),
+		backupLister:          management.Management.EtcdBackups(namespace).Controller().Lister(),
 		backupDriver:          service.NewEngineService(clusterprovisioner.NewPersistentStore(management.Core.Namespaces(""), management.Core)),
 		KontainerDriverLister: management.Management.KontainerDrivers("").Controller().Lister(),
+		secretLister:          management.Core.Secrets("").Controller().Lister(),
+		namespace:             namespace,
 	}
+	c.ActionHandler = NewActionHandler(c)
 
 	local := &rkedialerfactory.RKEDialerFactory{
 		Factory: management.Dialer,
@@ -69,6 +92,9 @@ func Register(ctx context.Context, management *config.ManagementContext) {
 
 	c.backupClient.AddLifecycle(ctx, "etcdbackup-controller", c)
 	go c.clusterBackupSync(ctx, clusterBackupCheckInterval)
+	go c.backupVerifySync(ctx, backupVerifyInterval)
+
+	RegisterRestore(ctx, management, namespace)
 }
 
 func (c *Controller) Create(b *v3.EtcdBackup) (runtime.Object, error) {
@@ -86,7 +112,11 @@ func (c *Controller) Create(b *v3.EtcdBackup) (runtime.Object, error) {
 	}
 
 	if !v3.BackupConditionCreated.IsTrue(b) {
-		b.Spec.Filename = generateBackupFilename(b.Name, cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig)
+		filename, err := c.generateBackupFilename(b.Name, cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig)
+		if err != nil {
+			return b, fmt.Errorf("[etcd-backup] failed to resolve backup destination: %v", err)
+		}
+		b.Spec.Filename = filename
 		b.Spec.BackupConfig = *cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig
 		v3.BackupConditionCreated.True(b)
 		// we set ConditionCompleted to Unknown to avoid incorrect "active" state
@@ -96,7 +126,7 @@ func (c *Controller) Create(b *v3.EtcdBackup) (runtime.Object, error) {
 			return b, err
 		}
 	}
-	bObj, saveErr := c.etcdSaveWithBackoff(b)
+	bObj, saveErr := c.getBackendProvider(b).Save(b)
 	b, err = c.backupClient.Update(bObj.(*v3.EtcdBackup))
 	if err != nil {
 		return b, err
@@ -110,23 +140,8 @@ func (c *Controller) Create(b *v3.EtcdBackup) (runtime.Object, error) {
 
 func (c *Controller) Remove(b *v3.EtcdBackup) (runtime.Object, error) {
 	logrus.Infof("[etcd-backup] Deleting backup %s ", b.Name)
-	if err := c.etcdRemoveSnapshotWithBackoff(b); err != nil {
-		logrus.Warnf("giving up on deleting backup [%s]: %v", b.Name, err)
-	}
-	if b.Spec.BackupConfig.S3BackupConfig == nil {
-		return b, nil
-	}
-	// try to remove from s3 for 3 times, then give up. if we don't we get stuck forever
-	var delErr error
-	for i := 0; i < 3; i++ {
-		if delErr = c.deleteS3Snapshot(b); delErr == nil {
-			break
-		}
-		logrus.Warnf("failed to delete backup from s3: %v", delErr)
-		time.Sleep(5 * time.Second)
-	}
-	if delErr != nil {
-		logrus.Warnf("giving up on deleting backup [%s] from s3: %v", b.Name, delErr)
+	if err := c.getBackendProvider(b).Remove(b); err != nil {
+		logrus.Warnf("%v", err)
 	}
 	return b, nil
 }
@@ -143,7 +158,7 @@ func (c *Controller) clusterBackupSync(ctx context.Context, interval time.Durati
 		case <-ctx.Done():
 			return nil
 		case <-tryTicker.C:
-			clusters, err := c.clusterLister.List("", labels.NewSelector())
+			clusters, err := c.clustersInScope()
 			if err != nil {
 				logrus.Error(fmt.Errorf("[etcd-backup] clusterBackupSync faild: %v", err))
 				return err
@@ -158,6 +173,19 @@ func (c *Controller) clusterBackupSync(ctx context.Context, interval time.Durati
 	}
 }
 
+// clustersInScope returns every cluster the controller is responsible for: all of them in
+// cluster-wide mode, or just the one matching c.namespace in namespace-scoped mode.
+func (c *Controller) clustersInScope() ([]*v3.Cluster, error) {
+	if c.namespace == "" {
+		return c.clusterLister.List("", labels.NewSelector())
+	}
+	cluster, err := c.clusterLister.Get("", c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	return []*v3.Cluster{cluster}, nil
+}
+
 func (c *Controller) doClusterBackupSync(cluster *v3.Cluster) error {
 	if cluster == nil || cluster.DeletionTimestamp != nil {
 		return nil
@@ -192,10 +220,20 @@ func (c *Controller) doClusterBackupSync(cluster *v3.Cluster) error {
 
 	// this cluster has backups, lets see if the last one is old enough
 	// a new backup is due if this is true
-	intervalHours := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig.IntervalHours
-	backupIntervalHours := time.Duration(intervalHours) * time.Hour
+	backupConfig := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig
+	nextBackupTime, err := getNextBackupTime(backupConfig, getBackupCompletedTime(newestBackup), newestBackup.Name)
+	if err != nil {
+		logrus.Warnf("[etcd-backup] Cluster [%s] has an invalid backup schedule, falling back to interval-hours: %v", cluster.Name, err)
+	}
 
-	if time.Since(getBackupCompletedTime(newestBackup)) > backupIntervalHours {
+	if nextBackupAt := nextBackupTime.Format(time.RFC3339); newestBackup.Status.NextBackupAt != nextBackupAt {
+		newestBackup.Status.NextBackupAt = nextBackupAt
+		if _, err := c.backupClient.Update(newestBackup); err != nil {
+			logrus.Warnf("[etcd-backup] Cluster [%s] failed to record next backup time: %v", cluster.Name, err)
+		}
+	}
+
+	if time.Now().After(nextBackupTime) {
 		newBackup, err := c.createNewBackup(cluster)
 		if err != nil {
 			return fmt.Errorf("[etcd-backup] Backup create failed:%v", err)
@@ -235,8 +273,15 @@ func (c *Controller) etcdSaveWithBackoff(b *v3.EtcdBackup) (runtime.Object, erro
 			}
 			return true, nil
 		})
+		if inErr != nil {
+			return b, inErr
+		}
 
-		return b, inErr
+		if verifyErr := c.recordAndVerifySnapshot(b); verifyErr != nil {
+			return b, verifyErr
+		}
+
+		return b, nil
 	})
 	if err != nil {
 		v3.BackupConditionCompleted.False(bObj)
@@ -267,9 +312,8 @@ func (c *Controller) etcdRemoveSnapshotWithBackoff(b *v3.EtcdBackup) error {
 }
 
 func (c *Controller) rotateExpiredBackups(cluster *v3.Cluster, clusterBackups []*v3.EtcdBackup) error {
-	retention := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig.Retention
-	intervalHours := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig.IntervalHours
-	expiredBackups := getExpiredBackups(retention, intervalHours, clusterBackups)
+	backupConfig := cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig
+	expiredBackups := getExpiredBackups(cluster.Name, backupConfig.Retention, backupConfig.IntervalHours, backupConfig.RetentionMaxAge, clusterBackups)
 	for _, backup := range expiredBackups {
 		if backup.Spec.Manual {
 			continue
@@ -283,15 +327,12 @@ func (c *Controller) rotateExpiredBackups(cluster *v3.Cluster, clusterBackups []
 
 func NewBackupObject(cluster *v3.Cluster, manual bool) *v3.EtcdBackup {
 	controller := true
-	typeFlag := "r"     // recurring is the default
-	providerFlag := "l" // local is the default
+	typeFlag := "r" // recurring is the default
 
 	if manual {
 		typeFlag = "m" // manual backup
 	}
-	if cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig.S3BackupConfig != nil {
-		providerFlag = "s" // s3 backup
-	}
+	providerFlag := targetFlag(cluster.Spec.RancherKubernetesEngineConfig.Services.Etcd.BackupConfig)
 	prefix := fmt.Sprintf("%s-%s%s-", cluster.Name, typeFlag, providerFlag)
 	return &v3.EtcdBackup{
 		ObjectMeta: metav1.ObjectMeta{
@@ -314,74 +355,78 @@ func NewBackupObject(cluster *v3.Cluster, manual bool) *v3.EtcdBackup {
 	}
 }
 
-func generateBackupFilename(snapshotName string, backupConfig *v3.BackupConfig) string {
+// generateBackupFilename builds the destination name the RKE snapshot tool saves the snapshot
+// to. For a remote target (s3, gcs or azure) the name is a full destination URL, so the node
+// streams the snapshot straight to that store itself instead of the controller reading the
+// snapshot back afterwards; for S3 it resolves CredentialsSecretRef first, the same way
+// GetS3Client does, so a cluster that only names a Secret (and otherwise leaves
+// S3BackupConfig empty) still gets a valid upload URL instead of one built from empty fields.
+func (c *Controller) generateBackupFilename(snapshotName string, backupConfig *v3.BackupConfig) (string, error) {
 	// no backup config
 	if backupConfig == nil {
-		return ""
+		return "", nil
 	}
 	filename := fmt.Sprintf("%s_%s.%s", snapshotName, time.Now().Format(time.RFC3339), compressedExtension)
 	if backupConfig.SafeTimestamp {
 		filename = strings.ReplaceAll(filename, ":", "-")
 	}
-	// s3 backup
-	if backupConfig != nil &&
-		backupConfig.S3BackupConfig != nil {
-		if len(backupConfig.S3BackupConfig.Folder) != 0 {
-			return fmt.Sprintf("https://%s/%s/%s/%s", backupConfig.S3BackupConfig.Endpoint, backupConfig.S3BackupConfig.BucketName, backupConfig.S3BackupConfig.Folder, filename)
+	switch backupConfig.Target {
+	case v3.BackupTargetGCS:
+		gbc := backupConfig.GCSBackupConfig
+		if gbc == nil {
+			return "", fmt.Errorf("[etcd-backup] gcs backup target selected but no GCSBackupConfig present")
 		}
-		return fmt.Sprintf("https://%s/%s/%s", backupConfig.S3BackupConfig.Endpoint, backupConfig.S3BackupConfig.BucketName, filename)
-	}
-	// local backup
-	return filename
-
-}
-
-func (c *Controller) deleteS3Snapshot(b *v3.EtcdBackup) error {
-	if b.Spec.BackupConfig.S3BackupConfig == nil {
-		return fmt.Errorf("Can't find S3 backup target configuration")
-	}
-	bucket := b.Spec.BackupConfig.S3BackupConfig.BucketName
-	folder := b.Spec.BackupConfig.S3BackupConfig.Folder
-
-	s3Client, err := GetS3Client(b.Spec.BackupConfig.S3BackupConfig, defaultTransportTimeout)
-	if err != nil {
-		return err
-	}
-
-	bucketExists, err := s3Client.BucketExists(bucket)
-	if err != nil {
-		return fmt.Errorf("can't access bucket: %v", err)
-	}
-	if !bucketExists {
-		logrus.Errorf("bucket %s doesn't exist", bucket)
-		return nil
-	}
-
-	// Extract filename from etcdBackup.Spec.Filename
-	var fileName string
-	fileName, err = clusterprovisioner.GetBackupFilenameFromURL(b.Spec.Filename)
-	if err != nil {
-		logrus.Warningf("Could not get filename from [%s]: %v. Using %s as fallback", b.Spec.Filename, err, b.Name)
-		fileName = b.Name
-	}
-
-	if len(folder) != 0 {
-		fileName = fmt.Sprintf("%s/%s", folder, fileName)
+		if len(gbc.Folder) != 0 {
+			return fmt.Sprintf("gs://%s/%s/%s", gbc.BucketName, gbc.Folder, filename), nil
+		}
+		return fmt.Sprintf("gs://%s/%s", gbc.BucketName, filename), nil
+	case v3.BackupTargetAzure:
+		abc := backupConfig.AzureBackupConfig
+		if abc == nil {
+			return "", fmt.Errorf("[etcd-backup] azure backup target selected but no AzureBackupConfig present")
+		}
+		if len(abc.Folder) != 0 {
+			return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s/%s", abc.AccountName, abc.ContainerName, abc.Folder, filename), nil
+		}
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", abc.AccountName, abc.ContainerName, filename), nil
+	case v3.BackupTargetLocal:
+		return filename, nil
+	default:
+		// s3, or an older config that predates Target and only ever supported s3/local
+		if backupConfig.S3BackupConfig == nil {
+			return filename, nil
+		}
+		sbc, err := resolveS3BackupConfig(backupConfig.S3BackupConfig, c.secretLister)
+		if err != nil {
+			return "", err
+		}
+		if len(sbc.Folder) != 0 {
+			return fmt.Sprintf("https://%s/%s/%s/%s", sbc.Endpoint, sbc.BucketName, sbc.Folder, filename), nil
+		}
+		return fmt.Sprintf("https://%s/%s/%s", sbc.Endpoint, sbc.BucketName, filename), nil
 	}
-	return s3Client.RemoveObject(bucket, fileName)
 }
 
-func GetS3Client(sbc *v3.S3BackupConfig, timeout int) (*minio.Client, error) {
+// GetS3Client builds a minio client for the given S3BackupConfig. When sbc.CredentialsSecretRef
+// names a Secret, its keys take precedence over the fields stored on the spec (so credential
+// rotation is picked up on the next backup/restore attempt without editing the cluster), and
+// falls back to IAM when neither the Secret nor the spec carry an access/secret key pair.
+func GetS3Client(sbc *v3.S3BackupConfig, timeout int, secretLister v1.SecretLister) (*minio.Client, error) {
 	if sbc == nil {
 		return nil, fmt.Errorf("Can't find S3 backup target configuration")
 	}
+	sbc, err := resolveS3BackupConfig(sbc, secretLister)
+	if err != nil {
+		return nil, err
+	}
 	var s3Client = &minio.Client{}
 	var creds *credentials.Credentials
-	var tr = http.DefaultTransport
-	tr.(*http.Transport).DialContext = (&net.Dialer{
-		Timeout:   time.Duration(timeout) * time.Second,
-		KeepAlive: 30 * time.Second,
-	}).DialContext
+	var tr http.RoundTripper = &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(timeout) * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+	}
 	endpoint := sbc.Endpoint
 	// no access credentials, we assume IAM roles
 	if sbc.AccessKey == "" ||
@@ -397,7 +442,7 @@ func GetS3Client(sbc *v3.S3BackupConfig, timeout int) (*minio.Client, error) {
 	}
 
 	bucketLookup := getBucketLookupType(endpoint)
-	s3Client, err := minio.NewWithOptions(endpoint, &minio.Options{
+	s3Client, err = minio.NewWithOptions(endpoint, &minio.Options{
 		Creds:        creds,
 		Region:       sbc.Region,
 		Secure:       true,
@@ -408,11 +453,87 @@ func GetS3Client(sbc *v3.S3BackupConfig, timeout int) (*minio.Client, error) {
 	}
 	if sbc.CustomCA != "" {
 		tr = getCustomCATransport(tr, sbc.CustomCA)
-		s3Client.SetCustomTransport(tr)
 	}
+	if sbc.ProxyURL != "" {
+		proxyTr, err := getProxyTransport(tr, sbc.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3 backup proxy url: %v", err)
+		}
+		tr = proxyTr
+	}
+	s3Client.SetCustomTransport(tr)
 	return s3Client, nil
 }
 
+// resolveS3BackupConfig returns a copy of sbc with values from the referenced Secret (if any)
+// overlaid on top of it. The Secret is re-read on every call so rotated credentials and
+// reconfigured endpoints take effect without editing the cluster. Recognized Secret keys are
+// accesskey, secretkey, endpoint, region, bucket, folder, customca and proxyurl. If the Secret
+// has been deleted or renamed and sbc itself carries no static AccessKey/SecretKey, that's
+// read as IAM being the desired auth mode, so the lookup failure is swallowed and sbc is
+// returned as-is instead of hard-failing - GetS3Client falls back to IAM when both keys are
+// empty. Any other lookup failure is still reported, since it doesn't tell us IAM was the
+// intent; once the Secret is found its keys are overlaid without further validation.
+func resolveS3BackupConfig(sbc *v3.S3BackupConfig, secretLister v1.SecretLister) (*v3.S3BackupConfig, error) {
+	if sbc == nil || sbc.CredentialsSecretRef == nil || secretLister == nil {
+		return sbc, nil
+	}
+	ns := sbc.CredentialsSecretRef.Namespace
+	if ns == "" {
+		ns = "cattle-system"
+	}
+	secret, err := secretLister.Get(ns, sbc.CredentialsSecretRef.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) && sbc.AccessKey == "" && sbc.SecretKey == "" {
+			logrus.Warnf("[etcd-backup] s3 backup credentials secret %s/%s not found, falling back to IAM", ns, sbc.CredentialsSecretRef.Name)
+			return sbc, nil
+		}
+		return nil, fmt.Errorf("failed to look up s3 backup credentials secret %s/%s: %v", ns, sbc.CredentialsSecretRef.Name, err)
+	}
+
+	resolved := sbc.DeepCopy()
+	if v, ok := secret.Data["accesskey"]; ok {
+		resolved.AccessKey = string(v)
+	}
+	if v, ok := secret.Data["secretkey"]; ok {
+		resolved.SecretKey = string(v)
+	}
+	if v, ok := secret.Data["endpoint"]; ok {
+		resolved.Endpoint = string(v)
+	}
+	if v, ok := secret.Data["region"]; ok {
+		resolved.Region = string(v)
+	}
+	if v, ok := secret.Data["bucket"]; ok {
+		resolved.BucketName = string(v)
+	}
+	if v, ok := secret.Data["folder"]; ok {
+		resolved.Folder = string(v)
+	}
+	if v, ok := secret.Data["customca"]; ok {
+		resolved.CustomCA = string(v)
+	}
+	if v, ok := secret.Data["proxyurl"]; ok {
+		resolved.ProxyURL = string(v)
+	}
+	return resolved, nil
+}
+
+// getProxyTransport wraps tr so requests to the S3 endpoint go through proxyURL, without
+// touching the process-wide HTTP_PROXY/HTTPS_PROXY environment variables.
+func getProxyTransport(tr http.RoundTripper, proxyURL string) (http.RoundTripper, error) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	httpTr, ok := tr.(*http.Transport)
+	if !ok {
+		httpTr = &http.Transport{}
+	}
+	httpTr.Proxy = http.ProxyURL(parsed)
+	return httpTr, nil
+}
+
 func (c *Controller) getRecuringBackupsList(cluster *v3.Cluster) ([]*v3.EtcdBackup, error) {
 	retList := []*v3.EtcdBackup{}
 	backups, err := c.backupLister.List(cluster.Name, labels.NewSelector())
@@ -442,17 +563,62 @@ func getBackupCompletedTime(o runtime.Object) time.Time {
 	return t
 }
 
-func getExpiredBackups(retention, intervalHours int, backups []*v3.EtcdBackup) []*v3.EtcdBackup {
+// getExpiredBackups returns backups that are past the count-based retention window, the
+// max-age retention window (when set), or both. maxAge is a Go duration string such as "720h";
+// an empty value disables the max-age dimension, and an unparseable one also disables it, but
+// logs a warning so an operator typo doesn't silently stop enforcing it.
+func getExpiredBackups(clusterName string, retention, intervalHours int, maxAge string, backups []*v3.EtcdBackup) []*v3.EtcdBackup {
 	expiredList := []*v3.EtcdBackup{}
 	toKeepDuration := time.Duration(retention*intervalHours) * time.Hour
+	maxAgeDuration, maxAgeErr := time.ParseDuration(maxAge)
+	if maxAgeErr != nil && maxAge != "" {
+		logrus.Warnf("[etcd-backup] Cluster [%s] has an invalid backup retention-max-age %q, ignoring it: %v", clusterName, maxAge, maxAgeErr)
+	}
 	for _, backup := range backups {
-		if time.Since(getBackupCompletedTime(backup)) > toKeepDuration {
+		age := time.Since(getBackupCompletedTime(backup))
+		expired := age > toKeepDuration
+		if maxAgeErr == nil && maxAgeDuration > 0 {
+			expired = expired || age > maxAgeDuration
+		}
+		if expired {
 			expiredList = append(expiredList, backup)
 		}
 	}
 	return expiredList
 }
 
+// getNextBackupTime computes when the next recurring backup is due. A CronSchedule takes
+// precedence over IntervalHours when set, so clusters can express e.g. "daily at 02:00 UTC"
+// instead of only a fixed interval; JitterSeconds, if set, randomizes the computed time within
+// that many seconds to avoid a thundering herd of backups firing at the same instant. jitter is
+// derived deterministically from seed (normally the current newest backup's name) rather than
+// re-rolled on every call, so repeated calls within the same backup cycle (e.g. once per
+// clusterBackupCheckInterval tick) agree on the same instant instead of producing a new random
+// NextBackupAt every tick.
+func getNextBackupTime(backupConfig *v3.BackupConfig, lastBackup time.Time, seed string) (time.Time, error) {
+	if backupConfig.CronSchedule != "" {
+		schedule, err := cron.ParseStandard(backupConfig.CronSchedule)
+		if err != nil {
+			return lastBackup.Add(time.Duration(backupConfig.IntervalHours) * time.Hour), fmt.Errorf("invalid cron schedule %q: %v", backupConfig.CronSchedule, err)
+		}
+		next := schedule.Next(lastBackup)
+		return next.Add(jitter(backupConfig.JitterSeconds, seed)), nil
+	}
+	next := lastBackup.Add(time.Duration(backupConfig.IntervalHours) * time.Hour)
+	return next.Add(jitter(backupConfig.JitterSeconds, seed)), nil
+}
+
+// jitter deterministically maps seed to a duration in [0, seconds), so the same seed always
+// produces the same jitter instead of a new random value each call.
+func jitter(seconds int, seed string) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	sum := fnv.New32a()
+	sum.Write([]byte(seed))
+	return time.Duration(sum.Sum32()%uint32(seconds)) * time.Second
+}
+
 func shouldBackup(cluster *v3.Cluster) bool {
 	// not an rke cluster, we do nothing
 	if cluster.Spec.RancherKubernetesEngineConfig == nil {
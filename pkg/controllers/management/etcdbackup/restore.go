@@ -0,0 +1,206 @@
+package etcdbackup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/rancher/kontainer-engine/service"
+	"github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
+	v1 "github.com/rancher/types/apis/core/v1"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	"github.com/rancher/types/config"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RestoreController drives EtcdRestore objects to completion. It mirrors Controller's shape
+// and backoff conventions so the two reconcilers read the same way, but restores are always
+// one-shot: once RestoreConditionCompleted is set the object is never touched again.
+type RestoreController struct {
+	ctx                   context.Context
+	clusterClient         v3.ClusterInterface
+	restoreClient         v3.EtcdRestoreInterface
+	backupLister          v3.EtcdBackupLister
+	backupDriver          *service.EngineService
+	KontainerDriverLister v3.KontainerDriverLister
+	secretLister          v1.SecretLister
+	// namespace mirrors Controller.namespace: empty means cluster-wide, otherwise the
+	// controller only reconciles EtcdRestores for the one cluster named by namespace.
+	namespace string
+}
+
+// RegisterRestore wires up the EtcdRestore lifecycle alongside the EtcdBackup controller, in
+// the same namespace scope so a --cluster-wide=false deployment doesn't end up with every
+// per-cluster instance racing on every cluster's restores.
+func RegisterRestore(ctx context.Context, management *config.ManagementContext, namespace string) {
+	rc := &RestoreController{
+		ctx:                   ctx,
+		clusterClient:         management.Management.Clusters(""),
+		restoreClient:         management.Management.EtcdRestores(namespace),
+		backupLister:          management.Management.EtcdBackups(namespace).Controller().Lister(),
+		backupDriver:          service.NewEngineService(clusterprovisioner.NewPersistentStore(management.Core.Namespaces(""), management.Core)),
+		KontainerDriverLister: management.Management.KontainerDrivers("").Controller().Lister(),
+		secretLister:          management.Core.Secrets("").Controller().Lister(),
+		namespace:             namespace,
+	}
+	rc.restoreClient.AddLifecycle(ctx, "etcdrestore-controller", rc)
+}
+
+func (rc *RestoreController) Create(r *v3.EtcdRestore) (runtime.Object, error) {
+	if v3.RestoreConditionCompleted.IsFalse(r) || v3.RestoreConditionCompleted.IsTrue(r) {
+		return r, nil
+	}
+
+	cluster, err := rc.clusterClient.Get(r.Spec.ClusterID, metav1.GetOptions{})
+	if err != nil {
+		return r, err
+	}
+
+	backup, backupConfig, err := rc.resolveBackup(r, cluster)
+	if err != nil {
+		return r, err
+	}
+
+	if !v3.RestoreConditionCreated.IsTrue(r) {
+		if err := rc.verifySnapshot(backup, backupConfig); err != nil {
+			v3.RestoreConditionCreated.False(r)
+			v3.RestoreConditionCreated.ReasonAndMessageFromError(r, err)
+			return rc.restoreClient.Update(r)
+		}
+		v3.RestoreConditionCreated.True(r)
+		r, err = rc.restoreClient.Update(r)
+		if err != nil {
+			return r, err
+		}
+	}
+
+	rObj, restoreErr := rc.etcdRestoreWithBackoff(r, cluster, backup)
+	r, err = rc.restoreClient.Update(rObj.(*v3.EtcdRestore))
+	if err != nil {
+		return r, err
+	}
+	if restoreErr != nil {
+		return r, fmt.Errorf("[etcd-restore] failed to restore snapshot: %v", restoreErr)
+	}
+	return r, nil
+}
+
+func (rc *RestoreController) Remove(r *v3.EtcdRestore) (runtime.Object, error) {
+	return r, nil
+}
+
+func (rc *RestoreController) Updated(r *v3.EtcdRestore) (runtime.Object, error) {
+	return r, nil
+}
+
+// resolveBackup returns the EtcdBackup and BackupConfig a restore should pull from, whether it
+// was specified by name or as an ad hoc S3 URL + credentials on the EtcdRestore spec itself.
+func (rc *RestoreController) resolveBackup(r *v3.EtcdRestore, cluster *v3.Cluster) (*v3.EtcdBackup, *v3.BackupConfig, error) {
+	if r.Spec.BackupName != "" {
+		backup, err := rc.backupLister.Get(cluster.Name, r.Spec.BackupName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[etcd-restore] can't find backup %s: %v", r.Spec.BackupName, err)
+		}
+		return backup, &backup.Spec.BackupConfig, nil
+	}
+	if r.Spec.S3BackupConfig == nil {
+		return nil, nil, fmt.Errorf("[etcd-restore] restore must reference a backup name or an S3 target")
+	}
+	backup := &v3.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: r.Name, Namespace: cluster.Name},
+		Spec: v3.EtcdBackupSpec{
+			ClusterID: cluster.Name,
+			Filename:  r.Spec.Filename,
+			BackupConfig: v3.BackupConfig{
+				S3BackupConfig: r.Spec.S3BackupConfig,
+			},
+		},
+	}
+	return backup, &backup.Spec.BackupConfig, nil
+}
+
+// verifySnapshot downloads the snapshot object from its BackupStore (s3, gcs or azure), checks
+// that it unzips to a single file, and compares the SHA256 of the downloaded object against
+// backup.Status.Checksum (computed over the same stored object by recordAndVerifySnapshot)
+// before the RKE driver is ever invoked, so a corrupted or tampered snapshot is caught before it
+// gets applied to the cluster.
+func (rc *RestoreController) verifySnapshot(backup *v3.EtcdBackup, backupConfig *v3.BackupConfig) error {
+	if isLocalTarget(backupConfig) || backup.Status.Checksum == "" {
+		// nothing to verify against, fall back to trusting the RKE driver's own checks
+		return nil
+	}
+	store, err := getBackupStoreWithSecrets(backupConfig, rc.secretLister)
+	if err != nil {
+		return err
+	}
+	obj, err := store.Get(backupObjectName(backup))
+	if err != nil {
+		return fmt.Errorf("failed to download snapshot for verification: %v", err)
+	}
+	defer obj.Close()
+
+	data, err := ioutil.ReadAll(obj)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot for verification: %v", err)
+	}
+	if _, err := unzipSnapshot(data); err != nil {
+		return fmt.Errorf("failed to unzip snapshot for verification: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum != backup.Status.Checksum {
+		return fmt.Errorf("snapshot checksum mismatch: expected %s, got %s", backup.Status.Checksum, checksum)
+	}
+	return nil
+}
+
+func unzipSnapshot(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if len(zr.File) != 1 {
+		return nil, fmt.Errorf("expected exactly one file in snapshot archive, got %d", len(zr.File))
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (rc *RestoreController) etcdRestoreWithBackoff(r *v3.EtcdRestore, cluster *v3.Cluster, backup *v3.EtcdBackup) (runtime.Object, error) {
+	backoff := getBackoff()
+	kontainerDriver, err := rc.KontainerDriverLister.Get("", service.RancherKubernetesEngineDriverName)
+	if err != nil {
+		return r, err
+	}
+
+	rObj, err := v3.RestoreConditionCompleted.Do(r, func() (runtime.Object, error) {
+		var inErr error
+		snapshotName := clusterprovisioner.GetBackupFilename(backup)
+		err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+			if inErr = rc.backupDriver.ETCDRestore(rc.ctx, cluster.Name, kontainerDriver, cluster.Spec, snapshotName, !isLocalTarget(&backup.Spec.BackupConfig)); inErr != nil {
+				logrus.Warnf("%v", inErr)
+				return false, nil
+			}
+			return true, nil
+		})
+		return r, err
+	})
+	if err != nil {
+		v3.RestoreConditionCompleted.False(rObj)
+		v3.RestoreConditionCompleted.ReasonAndMessageFromError(rObj, err)
+		return rObj, err
+	}
+	return rObj, nil
+}
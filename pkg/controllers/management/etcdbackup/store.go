@@ -0,0 +1,109 @@
+package etcdbackup
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rancher/rancher/pkg/controllers/management/clusterprovisioner"
+	v1 "github.com/rancher/types/apis/core/v1"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// BackupObjectInfo describes one object in a BackupStore, as returned by Stat and List.
+type BackupObjectInfo struct {
+	Name string
+	Size int64
+}
+
+// BackupStore is the target-agnostic surface the controller needs to manage etcd snapshots
+// off-cluster. S3, GCS and Azure Blob each implement it so the reconciler doesn't need to
+// know which one a given EtcdBackup is using.
+type BackupStore interface {
+	Put(name string, data io.Reader, size int64) error
+	Get(name string) (io.ReadCloser, error)
+	Stat(name string) (*BackupObjectInfo, error)
+	Delete(name string) error
+	List(prefix string) ([]BackupObjectInfo, error)
+}
+
+// getBackupStore picks the BackupStore implementation for a backup config's Target. Target is
+// a discriminated union ({s3|gcs|azure|local}); only one of the per-target config structs on
+// backupConfig is expected to be set, matching the one named by Target.
+func (c *Controller) getBackupStore(backupConfig *v3.BackupConfig) (BackupStore, error) {
+	return getBackupStoreWithSecrets(backupConfig, c.secretLister)
+}
+
+func getBackupStoreWithSecrets(backupConfig *v3.BackupConfig, secretLister v1.SecretLister) (BackupStore, error) {
+	if backupConfig == nil {
+		return nil, fmt.Errorf("[etcd-backup] cluster doesn't have a backup config")
+	}
+	switch backupConfig.Target {
+	case v3.BackupTargetGCS:
+		return newGCSBackupStore(backupConfig.GCSBackupConfig, secretLister)
+	case v3.BackupTargetAzure:
+		return newAzureBackupStore(backupConfig.AzureBackupConfig, secretLister)
+	case v3.BackupTargetS3, "":
+		// empty Target means an older backup config that only ever supported S3/local
+		if backupConfig.S3BackupConfig == nil {
+			return nil, fmt.Errorf("[etcd-backup] s3 backup target selected but no S3BackupConfig present")
+		}
+		return newS3BackupStore(backupConfig.S3BackupConfig, secretLister)
+	case v3.BackupTargetLocal:
+		return nil, fmt.Errorf("[etcd-backup] local backups have no off-cluster store")
+	default:
+		return nil, fmt.Errorf("[etcd-backup] unknown backup target %q", backupConfig.Target)
+	}
+}
+
+// isLocalTarget reports whether backupConfig has no off-cluster store at all, i.e. the backend
+// provider should never touch a BackupStore for it.
+func isLocalTarget(backupConfig *v3.BackupConfig) bool {
+	if backupConfig == nil {
+		return true
+	}
+	switch backupConfig.Target {
+	case v3.BackupTargetGCS, v3.BackupTargetAzure, v3.BackupTargetS3:
+		return false
+	case v3.BackupTargetLocal:
+		return true
+	default:
+		// older backup config that predates Target: presence of S3BackupConfig is what
+		// used to select S3 over local.
+		return backupConfig.S3BackupConfig == nil
+	}
+}
+
+// backupObjectName returns the bare name (no folder) a backup is (or will be) stored under in
+// its BackupStore. Every BackupStore implementation already prepends its own configured Folder
+// internally (see each store's key()), so callers must pass this bare name to Put/Get/Stat/
+// Delete rather than pre-folding the folder in themselves, or the folder ends up applied twice.
+func backupObjectName(b *v3.EtcdBackup) string {
+	fileName, err := clusterprovisioner.GetBackupFilenameFromURL(b.Spec.Filename)
+	if err != nil {
+		fileName = b.Name
+	}
+	return fileName
+}
+
+// targetFlag returns the short prefix letter NewBackupObject encodes into a backup's
+// GenerateName so the target is visible at a glance in `kubectl get etcdbackup`.
+func targetFlag(backupConfig *v3.BackupConfig) string {
+	if backupConfig == nil {
+		return "l"
+	}
+	switch backupConfig.Target {
+	case v3.BackupTargetGCS:
+		return "g"
+	case v3.BackupTargetAzure:
+		return "a"
+	case v3.BackupTargetS3:
+		return "s"
+	case v3.BackupTargetLocal:
+		return "l"
+	default:
+		if backupConfig.S3BackupConfig != nil {
+			return "s"
+		}
+		return "l"
+	}
+}
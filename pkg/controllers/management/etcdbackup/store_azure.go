@@ -0,0 +1,119 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	v1 "github.com/rancher/types/apis/core/v1"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// azureBackupStore stores snapshots in an Azure Blob container. AccountKey, like the S3 and
+// GCS credentials, is resolved from CredentialsSecretRef when one is set.
+type azureBackupStore struct {
+	containerURL azblob.ContainerURL
+	folder       string
+}
+
+func newAzureBackupStore(abc *v3.AzureBackupConfig, secretLister v1.SecretLister) (BackupStore, error) {
+	if abc == nil {
+		return nil, fmt.Errorf("[etcd-backup] azure backup target selected but no AzureBackupConfig present")
+	}
+	accountName, accountKey := abc.AccountName, abc.AccountKey
+	if abc.CredentialsSecretRef != nil {
+		ns := abc.CredentialsSecretRef.Namespace
+		if ns == "" {
+			ns = "cattle-system"
+		}
+		secret, err := secretLister.Get(ns, abc.CredentialsSecretRef.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up azure backup credentials secret %s/%s: %v", ns, abc.CredentialsSecretRef.Name, err)
+		}
+		if v, ok := secret.Data["accountname"]; ok {
+			accountName = string(v)
+		}
+		if v, ok := secret.Data["accountkey"]; ok {
+			accountKey = string(v)
+		}
+	}
+	cred, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := azblob.NewPipeline(cred, azblob.PipelineOptions{})
+	containerURL, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", accountName, abc.ContainerName))
+	if err != nil {
+		return nil, err
+	}
+	return &azureBackupStore{containerURL: azblob.NewContainerURL(*containerURL, pipeline), folder: abc.Folder}, nil
+}
+
+func (a *azureBackupStore) key(name string) string {
+	if a.folder == "" {
+		return name
+	}
+	return a.folder + "/" + name
+}
+
+func (a *azureBackupStore) blockBlob(name string) azblob.BlockBlobURL {
+	return a.containerURL.NewBlockBlobURL(a.key(name))
+}
+
+// unkey strips the folder prefix key adds, so List can hand back names that round-trip
+// through Get/Stat/Delete (which all re-apply key themselves) instead of double-prefixing.
+func (a *azureBackupStore) unkey(key string) string {
+	if a.folder == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, a.folder+"/")
+}
+
+func (a *azureBackupStore) Put(name string, data io.Reader, size int64) error {
+	body, err := ioutil.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	_, err = azblob.UploadBufferToBlockBlob(context.Background(), body, a.blockBlob(name), azblob.UploadToBlockBlobOptions{})
+	return err
+}
+
+func (a *azureBackupStore) Get(name string) (io.ReadCloser, error) {
+	resp, err := a.blockBlob(name).Download(context.Background(), 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body(azblob.RetryReaderOptions{}), nil
+}
+
+func (a *azureBackupStore) Stat(name string) (*BackupObjectInfo, error) {
+	props, err := a.blockBlob(name).GetProperties(context.Background(), azblob.BlobAccessConditions{})
+	if err != nil {
+		return nil, err
+	}
+	return &BackupObjectInfo{Name: name, Size: props.ContentLength()}, nil
+}
+
+func (a *azureBackupStore) Delete(name string) error {
+	_, err := a.blockBlob(name).Delete(context.Background(), azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
+	return err
+}
+
+func (a *azureBackupStore) List(prefix string) ([]BackupObjectInfo, error) {
+	var objs []BackupObjectInfo
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := a.containerURL.ListBlobsFlatSegment(context.Background(), marker, azblob.ListBlobsSegmentOptions{Prefix: a.key(prefix)})
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range resp.Segment.BlobItems {
+			objs = append(objs, BackupObjectInfo{Name: a.unkey(blob.Name), Size: *blob.Properties.ContentLength})
+		}
+		marker = resp.NextMarker
+	}
+	return objs, nil
+}
@@ -0,0 +1,107 @@
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	v1 "github.com/rancher/types/apis/core/v1"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// gcsBackupStore stores snapshots in a Google Cloud Storage bucket. Like S3BackupConfig, a
+// CredentialsSecretRef can name a Secret carrying the service account JSON key so it never has
+// to be stored on the cluster spec.
+type gcsBackupStore struct {
+	client *storage.Client
+	bucket string
+	folder string
+}
+
+func newGCSBackupStore(gbc *v3.GCSBackupConfig, secretLister v1.SecretLister) (BackupStore, error) {
+	if gbc == nil {
+		return nil, fmt.Errorf("[etcd-backup] gcs backup target selected but no GCSBackupConfig present")
+	}
+	ctx := context.Background()
+	var opts []option.ClientOption
+	if gbc.CredentialsSecretRef != nil {
+		ns := gbc.CredentialsSecretRef.Namespace
+		if ns == "" {
+			ns = "cattle-system"
+		}
+		secret, err := secretLister.Get(ns, gbc.CredentialsSecretRef.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up gcs backup credentials secret %s/%s: %v", ns, gbc.CredentialsSecretRef.Name, err)
+		}
+		if key, ok := secret.Data["serviceaccountjson"]; ok {
+			opts = append(opts, option.WithCredentialsJSON(key))
+		}
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsBackupStore{client: client, bucket: gbc.BucketName, folder: gbc.Folder}, nil
+}
+
+func (g *gcsBackupStore) key(name string) string {
+	if g.folder == "" {
+		return name
+	}
+	return g.folder + "/" + name
+}
+
+// unkey strips the folder prefix key adds, so List can hand back names that round-trip
+// through Get/Stat/Delete (which all re-apply key themselves) instead of double-prefixing.
+func (g *gcsBackupStore) unkey(key string) string {
+	if g.folder == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, g.folder+"/")
+}
+
+func (g *gcsBackupStore) Put(name string, data io.Reader, size int64) error {
+	ctx := context.Background()
+	w := g.client.Bucket(g.bucket).Object(g.key(name)).NewWriter(ctx)
+	if _, err := io.Copy(w, data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (g *gcsBackupStore) Get(name string) (io.ReadCloser, error) {
+	return g.client.Bucket(g.bucket).Object(g.key(name)).NewReader(context.Background())
+}
+
+func (g *gcsBackupStore) Stat(name string) (*BackupObjectInfo, error) {
+	attrs, err := g.client.Bucket(g.bucket).Object(g.key(name)).Attrs(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &BackupObjectInfo{Name: name, Size: attrs.Size}, nil
+}
+
+func (g *gcsBackupStore) Delete(name string) error {
+	return g.client.Bucket(g.bucket).Object(g.key(name)).Delete(context.Background())
+}
+
+func (g *gcsBackupStore) List(prefix string) ([]BackupObjectInfo, error) {
+	it := g.client.Bucket(g.bucket).Objects(context.Background(), &storage.Query{Prefix: g.key(prefix)})
+	var objs []BackupObjectInfo
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, BackupObjectInfo{Name: g.unkey(attrs.Name), Size: attrs.Size})
+	}
+	return objs, nil
+}
@@ -0,0 +1,84 @@
+package etcdbackup
+
+import (
+	"io"
+	"strings"
+
+	minio "github.com/minio/minio-go"
+	v1 "github.com/rancher/types/apis/core/v1"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// s3BackupStore adapts the existing minio-based client to the BackupStore interface.
+type s3BackupStore struct {
+	client *minio.Client
+	bucket string
+	folder string
+}
+
+func newS3BackupStore(sbc *v3.S3BackupConfig, secretLister v1.SecretLister) (BackupStore, error) {
+	client, err := GetS3Client(sbc, defaultTransportTimeout, secretLister)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolveS3BackupConfig(sbc, secretLister)
+	if err != nil {
+		return nil, err
+	}
+	return &s3BackupStore{client: client, bucket: resolved.BucketName, folder: resolved.Folder}, nil
+}
+
+func (s *s3BackupStore) key(name string) string {
+	if s.folder == "" {
+		return name
+	}
+	return s.folder + "/" + name
+}
+
+// unkey strips the folder prefix key adds, so List can hand back names that round-trip
+// through Get/Stat/Delete (which all re-apply key themselves) instead of double-prefixing.
+func (s *s3BackupStore) unkey(key string) string {
+	if s.folder == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, s.folder+"/")
+}
+
+func (s *s3BackupStore) Put(name string, data io.Reader, size int64) error {
+	_, err := s.client.PutObject(s.bucket, s.key(name), data, size, minio.PutObjectOptions{})
+	return err
+}
+
+func (s *s3BackupStore) Get(name string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(s.bucket, s.key(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *s3BackupStore) Stat(name string) (*BackupObjectInfo, error) {
+	info, err := s.client.StatObject(s.bucket, s.key(name), minio.StatObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &BackupObjectInfo{Name: name, Size: info.Size}, nil
+}
+
+func (s *s3BackupStore) Delete(name string) error {
+	return s.client.RemoveObject(s.bucket, s.key(name))
+}
+
+func (s *s3BackupStore) List(prefix string) ([]BackupObjectInfo, error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var objs []BackupObjectInfo
+	for obj := range s.client.ListObjects(s.bucket, s.key(prefix), true, doneCh) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		objs = append(objs, BackupObjectInfo{Name: s.unkey(obj.Key), Size: obj.Size})
+	}
+	return objs, nil
+}
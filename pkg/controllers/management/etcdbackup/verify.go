@@ -0,0 +1,139 @@
+package etcdbackup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	backupVerifyInterval = 1 * time.Hour
+	backupVerifySample   = 5
+)
+
+// recordAndVerifySnapshot is called right after a remote backend's Save finishes uploading.
+// It stats the object in the backup's BackupStore to record its size, and downloads it to
+// compute a SHA256 checksum, before BackupConditionCompleted is allowed to go true - so a
+// backup that claims to be completed is one we've actually confirmed landed in its store.
+func (c *Controller) recordAndVerifySnapshot(b *v3.EtcdBackup) error {
+	if isLocalTarget(&b.Spec.BackupConfig) {
+		return nil
+	}
+	store, err := c.getBackupStore(&b.Spec.BackupConfig)
+	if err != nil {
+		return err
+	}
+	name := backupObjectName(b)
+
+	info, err := store.Stat(name)
+	if err != nil {
+		return fmt.Errorf("snapshot missing from its store after upload: %v", err)
+	}
+	b.Status.Size = info.Size
+
+	checksum, err := hashStoredObject(store, name)
+	if err != nil {
+		return fmt.Errorf("failed to checksum uploaded snapshot: %v", err)
+	}
+	b.Status.Checksum = checksum
+	v3.BackupConditionVerified.True(b)
+	return nil
+}
+
+func hashStoredObject(store BackupStore, name string) (string, error) {
+	obj, err := store.Get(name)
+	if err != nil {
+		return "", err
+	}
+	defer obj.Close()
+
+	data, err := ioutil.ReadAll(obj)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// backupVerifySync periodically re-checks a random sample of already-completed backups so
+// silent bit-rot or a bucket lifecycle rule quietly deleting objects is caught before an
+// operator needs to restore and discovers the snapshot is gone.
+func (c *Controller) backupVerifySync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.verifyRandomSample(); err != nil {
+				logrus.Errorf("[etcd-backup] periodic verification failed: %v", err)
+			}
+		}
+	}
+}
+
+func (c *Controller) verifyRandomSample() error {
+	backups, err := c.backupLister.List("", labels.NewSelector())
+	if err != nil {
+		return err
+	}
+	completed := make([]*v3.EtcdBackup, 0, len(backups))
+	for _, b := range backups {
+		if v3.BackupConditionCompleted.IsTrue(b) && !isLocalTarget(&b.Spec.BackupConfig) {
+			completed = append(completed, b)
+		}
+	}
+	rand.Shuffle(len(completed), func(i, j int) { completed[i], completed[j] = completed[j], completed[i] })
+	if len(completed) > backupVerifySample {
+		completed = completed[:backupVerifySample]
+	}
+	for _, b := range completed {
+		if err := c.verifyStoredBackup(b); err != nil {
+			logrus.Warnf("[etcd-backup] backup [%s] failed verification: %v", b.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) verifyStoredBackup(b *v3.EtcdBackup) error {
+	store, err := c.getBackupStore(&b.Spec.BackupConfig)
+	if err != nil {
+		return c.markVerificationFailed(b, err)
+	}
+	name := backupObjectName(b)
+	if _, err := store.Stat(name); err != nil {
+		return c.markVerificationFailed(b, err)
+	}
+	if b.Status.Checksum != "" {
+		checksum, err := hashStoredObject(store, name)
+		if err != nil {
+			return c.markVerificationFailed(b, err)
+		}
+		if checksum != b.Status.Checksum {
+			return c.markVerificationFailed(b, fmt.Errorf("checksum mismatch: expected %s, got %s", b.Status.Checksum, checksum))
+		}
+	}
+	if !v3.BackupConditionVerified.IsTrue(b) {
+		v3.BackupConditionVerified.True(b)
+		_, err = c.backupClient.Update(b)
+	}
+	return err
+}
+
+func (c *Controller) markVerificationFailed(b *v3.EtcdBackup, cause error) error {
+	v3.BackupConditionVerified.False(b)
+	v3.BackupConditionVerified.ReasonAndMessageFromError(b, cause)
+	if _, err := c.backupClient.Update(b); err != nil {
+		return err
+	}
+	return cause
+}